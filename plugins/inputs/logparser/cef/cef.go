@@ -0,0 +1,193 @@
+// Package cef parses ArcSight Common Event Format (CEF) and IBM Log Event
+// Extended Format (LEEF) records, as emitted by firewalls, IDS/IPS and
+// other security appliances. The two formats share a pipe-delimited header
+// plus a key=value extension, but disagree on the number of header fields
+// and how the extension is delimited, so each gets its own header schema
+// and extension parsing.
+package cef
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// CEF: "CEF:Version|Device Vendor|Device Product|Device Version|
+//       Signature ID|Name|Severity|Extension"
+var cefHeaderFields = []string{
+	"version", "device_vendor", "device_product", "device_version",
+	"signature_id", "name", "severity",
+}
+
+// LEEF: "LEEF:Version|Vendor|Product Name|Product Version|EventID|
+//        [Delimiter|]Extension" - LEEF 1.0 has no Delimiter field and
+// always tab-delimits the extension; LEEF 2.0 adds it as an extra header
+// field.
+var leefHeaderFields = []string{
+	"version", "vendor", "product_name", "product_version", "event_id",
+}
+
+// cefExtensionPattern finds the "key=" tokens of a CEF extension string
+// ("key1=value one key2=value two"). Each value runs from its "key=" up to
+// the whitespace before the next "key=" token or end of string, so values
+// may contain spaces.
+var cefExtensionPattern = regexp.MustCompile(`(?:^|\s+)(\w+)=`)
+
+// Parser turns a single CEF or LEEF record into a telegraf.Metric. Every
+// header field becomes a tag; every extension key becomes a field, with
+// numeric-looking values coerced to int64/float64.
+type Parser struct {
+	MeasurementName string `toml:"measurement"`
+}
+
+func (p *Parser) Compile() error {
+	if p.MeasurementName == "" {
+		p.MeasurementName = "cef"
+	}
+	return nil
+}
+
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	switch {
+	case strings.HasPrefix(line, "CEF:"):
+		return p.parseCEF(strings.TrimPrefix(line, "CEF:"))
+	case strings.HasPrefix(line, "LEEF:"):
+		return p.parseLEEF(strings.TrimPrefix(line, "LEEF:"))
+	default:
+		return nil, fmt.Errorf("cef: line is not a CEF or LEEF record")
+	}
+}
+
+func (p *Parser) parseCEF(body string) (telegraf.Metric, error) {
+	parts := splitUnescaped(body, '|')
+	if len(parts) < len(cefHeaderFields) {
+		return nil, fmt.Errorf(
+			"cef: expected at least %d CEF header fields, got %d", len(cefHeaderFields), len(parts))
+	}
+
+	tags := map[string]string{"format": "CEF"}
+	for i, name := range cefHeaderFields {
+		tags[name] = unescape(parts[i])
+	}
+
+	fields := make(map[string]interface{})
+	if len(parts) > len(cefHeaderFields) {
+		extension := strings.Join(parts[len(cefHeaderFields):], "|")
+		locs := cefExtensionPattern.FindAllStringSubmatchIndex(extension, -1)
+		for i, loc := range locs {
+			end := len(extension)
+			if i+1 < len(locs) {
+				end = locs[i+1][0]
+			}
+			fields[extension[loc[2]:loc[3]]] = coerce(unescape(extension[loc[1]:end]))
+		}
+	}
+
+	return p.buildMetric(tags, fields)
+}
+
+func (p *Parser) parseLEEF(body string) (telegraf.Metric, error) {
+	parts := splitUnescaped(body, '|')
+	if len(parts) < len(leefHeaderFields) {
+		return nil, fmt.Errorf(
+			"cef: expected at least %d LEEF header fields, got %d", len(leefHeaderFields), len(parts))
+	}
+
+	tags := map[string]string{"format": "LEEF"}
+	for i, name := range leefHeaderFields {
+		tags[name] = unescape(parts[i])
+	}
+
+	rest := parts[len(leefHeaderFields):]
+
+	// LEEF 2.0 carries an explicit extension delimiter as the next field
+	// (a literal character, or "xHH" for a hex byte such as tab); LEEF
+	// 1.0 has no such field and always tab-delimits the extension.
+	delim := "\t"
+	if strings.HasPrefix(tags["version"], "2") && len(rest) > 0 {
+		delim = leefDelimiter(rest[0])
+		rest = rest[1:]
+	}
+
+	fields := make(map[string]interface{})
+	if extension := strings.Join(rest, "|"); extension != "" {
+		for _, tok := range strings.Split(extension, delim) {
+			kv := strings.SplitN(tok, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			fields[kv[0]] = coerce(unescape(kv[1]))
+		}
+	}
+
+	return p.buildMetric(tags, fields)
+}
+
+func (p *Parser) buildMetric(tags map[string]string, fields map[string]interface{}) (telegraf.Metric, error) {
+	if len(fields) == 0 {
+		fields["name"] = tags["name"]
+	}
+	return telegraf.NewMetric(p.MeasurementName, tags, fields, time.Now())
+}
+
+// coerce converts a numeric-looking extension value to int64/float64,
+// leaving it as a string otherwise.
+func coerce(value string) interface{} {
+	if iv, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return iv
+	}
+	if fv, err := strconv.ParseFloat(value, 64); err == nil {
+		return fv
+	}
+	return value
+}
+
+// leefDelimiter decodes a LEEF 2.0 delimiter field: either a literal
+// single character, or "xHH"/"XHH" for a hex-escaped byte (e.g. "x09" for
+// tab).
+func leefDelimiter(s string) string {
+	if len(s) == 3 && (s[0] == 'x' || s[0] == 'X') {
+		if b, err := strconv.ParseUint(s[1:], 16, 8); err == nil {
+			return string([]byte{byte(b)})
+		}
+	}
+	if s == "" {
+		return "\t"
+	}
+	return s
+}
+
+// splitUnescaped splits s on sep, treating a backslash-escaped sep as a
+// literal character rather than a delimiter.
+func splitUnescaped(s string, sep byte) []string {
+	var parts []string
+	var cur strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			cur.WriteByte(s[i])
+			cur.WriteByte(s[i+1])
+			i++
+			continue
+		}
+		if s[i] == sep {
+			parts = append(parts, cur.String())
+			cur.Reset()
+			continue
+		}
+		cur.WriteByte(s[i])
+	}
+	parts = append(parts, cur.String())
+	return parts
+}
+
+// unescape removes the backslash escaping CEF/LEEF apply to '|', '=' and
+// '\' within field values.
+func unescape(s string) string {
+	r := strings.NewReplacer(`\|`, "|", `\=`, "=", `\\`, `\`)
+	return r.Replace(s)
+}
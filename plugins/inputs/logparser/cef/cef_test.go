@@ -0,0 +1,85 @@
+package cef
+
+import "testing"
+
+func TestParseLineCEF(t *testing.T) {
+	p := &Parser{}
+	if err := p.Compile(); err != nil {
+		t.Fatalf("Compile failed: %s", err)
+	}
+
+	line := "CEF:0|Security|threatmanager|1.0|100|worm successfully stopped|10|src=10.0.0.1 dst=2.1.2.2 spt=1232"
+	m, err := p.ParseLine(line)
+	if err != nil {
+		t.Fatalf("ParseLine failed: %s", err)
+	}
+
+	if m.Tags()["format"] != "CEF" {
+		t.Errorf("got format=%q, want CEF", m.Tags()["format"])
+	}
+	if m.Tags()["device_product"] != "threatmanager" {
+		t.Errorf("got device_product=%q, want threatmanager", m.Tags()["device_product"])
+	}
+	if m.Tags()["severity"] != "10" {
+		t.Errorf("got severity=%q, want 10", m.Tags()["severity"])
+	}
+	if m.Fields()["src"] != "10.0.0.1" {
+		t.Errorf("got src=%v, want 10.0.0.1", m.Fields()["src"])
+	}
+	if v, ok := m.Fields()["spt"].(int64); !ok || v != 1232 {
+		t.Errorf("got spt=%v, want int64(1232)", m.Fields()["spt"])
+	}
+}
+
+func TestParseLineLEEF1HasNoSeverityAndTabDelimits(t *testing.T) {
+	p := &Parser{}
+	if err := p.Compile(); err != nil {
+		t.Fatalf("Compile failed: %s", err)
+	}
+
+	line := "LEEF:1.0|Vendor|Product|1.0|EventID|src=1.1.1.1\tdst=2.2.2.2"
+	m, err := p.ParseLine(line)
+	if err != nil {
+		t.Fatalf("ParseLine failed: %s", err)
+	}
+
+	if m.Tags()["format"] != "LEEF" {
+		t.Errorf("got format=%q, want LEEF", m.Tags()["format"])
+	}
+	if _, ok := m.Tags()["severity"]; ok {
+		t.Error("LEEF records have no severity header field, but one was set")
+	}
+	if m.Tags()["event_id"] != "EventID" {
+		t.Errorf("got event_id=%q, want EventID", m.Tags()["event_id"])
+	}
+	if m.Fields()["src"] != "1.1.1.1" || m.Fields()["dst"] != "2.2.2.2" {
+		t.Errorf("got fields %v, want src=1.1.1.1 dst=2.2.2.2", m.Fields())
+	}
+}
+
+func TestParseLineLEEF2ExplicitHexDelimiter(t *testing.T) {
+	p := &Parser{}
+	if err := p.Compile(); err != nil {
+		t.Fatalf("Compile failed: %s", err)
+	}
+
+	// LEEF 2.0 carries an explicit delimiter field; "x09" is a hex-escaped tab.
+	line := "LEEF:2.0|Vendor|Product|2.0|EventID|x09|src=1.1.1.1\tdst=2.2.2.2"
+	m, err := p.ParseLine(line)
+	if err != nil {
+		t.Fatalf("ParseLine failed: %s", err)
+	}
+	if m.Fields()["src"] != "1.1.1.1" || m.Fields()["dst"] != "2.2.2.2" {
+		t.Errorf("got fields %v, want src=1.1.1.1 dst=2.2.2.2", m.Fields())
+	}
+}
+
+func TestParseLineUnknownFormat(t *testing.T) {
+	p := &Parser{}
+	if err := p.Compile(); err != nil {
+		t.Fatalf("Compile failed: %s", err)
+	}
+	if _, err := p.ParseLine("not a cef or leef line"); err == nil {
+		t.Fatal("expected an error for a line with no CEF/LEEF prefix, got nil")
+	}
+}
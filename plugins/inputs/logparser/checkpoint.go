@@ -0,0 +1,189 @@
+package logparser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// fileCheckpoint is the persisted state for a single tailed file: enough to
+// know whether the file we find at Start is the same file we left off
+// reading, and if so, where to resume.
+type fileCheckpoint struct {
+	Filename string `json:"filename"`
+	Inode    uint64 `json:"inode"`
+	Offset   int64  `json:"offset"`
+}
+
+// Checkpointer periodically persists the read offset of every tailed file
+// to disk so that a restart can resume tailing at-least-once instead of
+// re-seeking to the beginning or end of each file.
+type Checkpointer struct {
+	path     string
+	interval time.Duration
+
+	sync.Mutex
+	checkpoints map[string]fileCheckpoint
+	dirty       bool
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewCheckpointer creates a Checkpointer backed by path, flushing dirty
+// state at the given interval. path may not yet exist.
+func NewCheckpointer(path string, interval time.Duration) *Checkpointer {
+	return &Checkpointer{
+		path:        path,
+		interval:    interval,
+		checkpoints: make(map[string]fileCheckpoint),
+	}
+}
+
+// Load reads any existing checkpoint file into memory. A missing file is
+// not an error; it simply leaves Checkpointer with no checkpoints. Callers
+// must use Seek, not the map this used to return, to look up a file's
+// checkpoint afterwards: the map backing Checkpointer keeps being written
+// by Update/Flush from other goroutines for as long as the Checkpointer is
+// running.
+func (c *Checkpointer) Load() error {
+	c.Lock()
+	defer c.Unlock()
+
+	b, err := ioutil.ReadFile(c.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var checkpoints []fileCheckpoint
+	if err := json.Unmarshal(b, &checkpoints); err != nil {
+		return fmt.Errorf("error parsing checkpoint file %s: %s", c.path, err)
+	}
+
+	for _, cp := range checkpoints {
+		c.checkpoints[cp.Filename] = cp
+	}
+	return nil
+}
+
+// Seek returns the saved checkpoint for filename, if any, safe to call
+// concurrently with Update/Flush from other goroutines.
+func (c *Checkpointer) Seek(filename string) (fileCheckpoint, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	cp, ok := c.checkpoints[filename]
+	return cp, ok
+}
+
+// Update records the latest known offset for filename and marks the
+// checkpoint state dirty so the next periodic flush (or Stop) persists it.
+func (c *Checkpointer) Update(filename string, inode uint64, offset int64) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.checkpoints[filename] = fileCheckpoint{
+		Filename: filename,
+		Inode:    inode,
+		Offset:   offset,
+	}
+	c.dirty = true
+}
+
+// Start launches the periodic flush goroutine. Start is a no-op if the
+// Checkpointer has no path configured.
+func (c *Checkpointer) Start() {
+	if c.path == "" {
+		return
+	}
+
+	c.stop = make(chan struct{})
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := c.Flush(); err != nil {
+					fmt.Printf("ERROR: logparser checkpoint flush failed: %s\n", err)
+				}
+			case <-c.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts the periodic flush goroutine and performs one final flush.
+func (c *Checkpointer) Stop() error {
+	if c.path == "" {
+		return nil
+	}
+
+	close(c.stop)
+	c.wg.Wait()
+	return c.Flush()
+}
+
+// Flush writes the current checkpoint state to disk if it has changed since
+// the last flush, via write-temp-then-rename so a crash mid-write can never
+// leave a corrupt checkpoint file behind.
+func (c *Checkpointer) Flush() error {
+	c.Lock()
+	defer c.Unlock()
+
+	if !c.dirty {
+		return nil
+	}
+
+	checkpoints := make([]fileCheckpoint, 0, len(c.checkpoints))
+	for _, cp := range c.checkpoints {
+		checkpoints = append(checkpoints, cp)
+	}
+
+	b, err := json.Marshal(checkpoints)
+	if err != nil {
+		return err
+	}
+
+	tmp := c.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return err
+	}
+
+	c.dirty = false
+	return nil
+}
+
+// fileInode returns the inode of the file at path, used to detect log
+// rotation/truncation between a checkpoint and the file we find on disk.
+func fileInode(path string) (uint64, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+
+	stat, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, fmt.Errorf("unable to determine inode for %s", path)
+	}
+	return stat.Ino, nil
+}
+
+// checkpointDir ensures the directory for the checkpoint file exists.
+func checkpointDir(path string) error {
+	return os.MkdirAll(filepath.Dir(path), 0755)
+}
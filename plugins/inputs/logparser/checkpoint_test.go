@@ -0,0 +1,86 @@
+package logparser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCheckpointerUpdateFlushLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logparser.checkpoint")
+
+	c := NewCheckpointer(path, time.Minute)
+	c.Update("/var/log/app.log", 42, 1024)
+
+	if err := c.Flush(); err != nil {
+		t.Fatalf("Flush failed: %s", err)
+	}
+
+	reloaded := NewCheckpointer(path, time.Minute)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Load failed: %s", err)
+	}
+
+	cp, ok := reloaded.Seek("/var/log/app.log")
+	if !ok {
+		t.Fatal("expected a checkpoint for /var/log/app.log after reload")
+	}
+	if cp.Inode != 42 || cp.Offset != 1024 {
+		t.Fatalf("got checkpoint %+v, want inode 42 offset 1024", cp)
+	}
+}
+
+func TestCheckpointerLoadMissingFileIsNotError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "does-not-exist.checkpoint")
+
+	c := NewCheckpointer(path, time.Minute)
+	if err := c.Load(); err != nil {
+		t.Fatalf("expected no error loading a missing checkpoint file, got %s", err)
+	}
+	if _, ok := c.Seek("/var/log/app.log"); ok {
+		t.Fatal("expected no checkpoint after loading a missing file")
+	}
+}
+
+func TestCheckpointerSeekIsConcurrencySafe(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logparser.checkpoint")
+	c := NewCheckpointer(path, time.Minute)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			c.Update("/var/log/app.log", 1, int64(i))
+		}
+	}()
+
+	for i := 0; i < 1000; i++ {
+		c.Seek("/var/log/app.log")
+	}
+	<-done
+}
+
+func TestCheckpointerFlushOnlyWritesWhenDirty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logparser.checkpoint")
+	c := NewCheckpointer(path, time.Minute)
+
+	if err := c.Flush(); err != nil {
+		t.Fatalf("Flush failed: %s", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected Flush of a clean checkpointer not to write %s, stat err: %v", path, err)
+	}
+
+	if err := c.Load(); err != nil {
+		t.Fatalf("Load failed: %s", err)
+	}
+}
+
+func TestCheckpointerStopWithNoPathIsNoop(t *testing.T) {
+	c := NewCheckpointer("", time.Minute)
+	c.Start()
+	if err := c.Stop(); err != nil {
+		t.Fatalf("Stop with no path should be a no-op, got error: %s", err)
+	}
+}
@@ -0,0 +1,143 @@
+package csv
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gobwas/glob"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+)
+
+// Parser turns a single CSV record into a telegraf.Metric. Column names
+// come either from an explicit ColumnNames list or from the first line fed
+// to ParseLine, when HeaderRowCount is 1.
+//
+// ParseLine learns ColumnNames from the header row, so a Parser is only
+// safe for a single file at a time. logparser calls Clone to give every
+// file its own copy before tailing it; callers that instead share one
+// Parser across concurrent files (or goroutines) should serialize their
+// own calls to ParseLine, which is why headerSeen/ColumnNames are still
+// guarded by mu below.
+type Parser struct {
+	MeasurementName string `toml:"measurement"`
+	HeaderRowCount  int    `toml:"header_row_count"`
+	ColumnNames     []string
+	Delimiter       string
+
+	TagColumns      []string `toml:"tag_columns"`
+	FieldColumnsInt []string `toml:"field_columns_int"`
+
+	tagColumns      glob.Glob
+	fieldColumnsInt glob.Glob
+
+	mu         sync.Mutex
+	headerSeen bool
+}
+
+// Clone returns a new Parser with the same configuration but its own,
+// unshared header/column-name state, so that multiple files tailed
+// through the same [inputs.logparser.csv] (or [[inputs.logparser.file]])
+// block don't learn conflicting schemas from each other's header rows.
+func (p *Parser) Clone() *Parser {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	clone := &Parser{
+		MeasurementName: p.MeasurementName,
+		HeaderRowCount:  p.HeaderRowCount,
+		ColumnNames:     p.ColumnNames,
+		Delimiter:       p.Delimiter,
+		TagColumns:      p.TagColumns,
+		FieldColumnsInt: p.FieldColumnsInt,
+		tagColumns:      p.tagColumns,
+		fieldColumnsInt: p.fieldColumnsInt,
+	}
+	if p.HeaderRowCount > 0 {
+		// column names are learned, not configured; the clone learns its
+		// own from whatever file it is given.
+		clone.ColumnNames = nil
+	}
+	return clone
+}
+
+func (p *Parser) Compile() error {
+	var err error
+
+	if p.MeasurementName == "" {
+		p.MeasurementName = "csv"
+	}
+	if p.HeaderRowCount == 0 && len(p.ColumnNames) == 0 {
+		return fmt.Errorf("csv: either header_row_count or column_names is required")
+	}
+
+	if len(p.TagColumns) > 0 {
+		if p.tagColumns, err = internal.CompileFilter(p.TagColumns); err != nil {
+			return err
+		}
+	}
+
+	if len(p.FieldColumnsInt) > 0 {
+		if p.fieldColumnsInt, err = internal.CompileFilter(p.FieldColumnsInt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	r := csv.NewReader(strings.NewReader(line))
+	if p.Delimiter != "" {
+		runes := []rune(p.Delimiter)
+		r.Comma = runes[0]
+	}
+
+	record, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("csv: %s", err)
+	}
+
+	p.mu.Lock()
+	if p.HeaderRowCount > 0 && !p.headerSeen {
+		p.ColumnNames = record
+		p.headerSeen = true
+		p.mu.Unlock()
+		return nil, nil
+	}
+	columnNames := p.ColumnNames
+	p.mu.Unlock()
+
+	if len(record) != len(columnNames) {
+		return nil, fmt.Errorf("csv: line has %d columns, expected %d", len(record), len(columnNames))
+	}
+
+	tags := make(map[string]string)
+	fields := make(map[string]interface{})
+	for i, value := range record {
+		name := columnNames[i]
+
+		if p.tagColumns != nil && p.tagColumns.Match(name) {
+			tags[name] = value
+			continue
+		}
+
+		if p.fieldColumnsInt != nil && p.fieldColumnsInt.Match(name) {
+			iv, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("csv: error converting column %q to int: %s", name, err)
+			}
+			fields[name] = iv
+			continue
+		}
+
+		fields[name] = value
+	}
+
+	return telegraf.NewMetric(p.MeasurementName, tags, fields, time.Now())
+}
@@ -0,0 +1,97 @@
+package csv
+
+import "testing"
+
+func TestParseLineLearnsHeaderThenRecords(t *testing.T) {
+	p := &Parser{HeaderRowCount: 1}
+	if err := p.Compile(); err != nil {
+		t.Fatalf("Compile failed: %s", err)
+	}
+
+	if m, err := p.ParseLine("host,bytes"); err != nil || m != nil {
+		t.Fatalf("header row: got metric %v err %v, want nil metric and nil error", m, err)
+	}
+
+	m, err := p.ParseLine("web1,200")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %s", err)
+	}
+	if m.Fields()["host"] != "web1" || m.Fields()["bytes"] != "200" {
+		t.Fatalf("got fields %v, want host=web1 bytes=200", m.Fields())
+	}
+}
+
+func TestParseLineTagAndIntColumns(t *testing.T) {
+	p := &Parser{
+		HeaderRowCount:  1,
+		TagColumns:      []string{"host"},
+		FieldColumnsInt: []string{"bytes"},
+	}
+	if err := p.Compile(); err != nil {
+		t.Fatalf("Compile failed: %s", err)
+	}
+	if _, err := p.ParseLine("host,bytes"); err != nil {
+		t.Fatalf("header row failed: %s", err)
+	}
+
+	m, err := p.ParseLine("web1,200")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %s", err)
+	}
+	if m.Tags()["host"] != "web1" {
+		t.Fatalf("got tags %v, want host=web1", m.Tags())
+	}
+	if v, ok := m.Fields()["bytes"].(int64); !ok || v != 200 {
+		t.Fatalf("got bytes=%v, want int64(200)", m.Fields()["bytes"])
+	}
+}
+
+func TestCloneDoesNotShareLearnedHeader(t *testing.T) {
+	p := &Parser{HeaderRowCount: 1}
+	if err := p.Compile(); err != nil {
+		t.Fatalf("Compile failed: %s", err)
+	}
+	if _, err := p.ParseLine("host,bytes"); err != nil {
+		t.Fatalf("header row on original failed: %s", err)
+	}
+
+	clone := p.Clone()
+
+	// The clone must relearn its own header from whatever file it is
+	// handed, not reuse the header the original parser already learned -
+	// otherwise one file's column names leak into another file's data.
+	m, err := clone.ParseLine("region,errors")
+	if err != nil {
+		t.Fatalf("header row on clone failed: %s", err)
+	}
+	if m != nil {
+		t.Fatalf("expected the clone's first line to be treated as its own header row, got metric %v", m)
+	}
+
+	m, err = clone.ParseLine("us-east,5")
+	if err != nil {
+		t.Fatalf("ParseLine on clone failed: %s", err)
+	}
+	if m.Fields()["region"] != "us-east" || m.Fields()["errors"] != "5" {
+		t.Fatalf("got fields %v, want region=us-east errors=5", m.Fields())
+	}
+
+	// The original parser's own state must be unaffected by the clone.
+	m, err = p.ParseLine("web1,200")
+	if err != nil {
+		t.Fatalf("ParseLine on original failed: %s", err)
+	}
+	if m.Fields()["host"] != "web1" || m.Fields()["bytes"] != "200" {
+		t.Fatalf("got fields %v, want host=web1 bytes=200 (original header unaffected by clone)", m.Fields())
+	}
+}
+
+func TestParseLineColumnCountMismatch(t *testing.T) {
+	p := &Parser{ColumnNames: []string{"a", "b"}}
+	if err := p.Compile(); err != nil {
+		t.Fatalf("Compile failed: %s", err)
+	}
+	if _, err := p.ParseLine("1,2,3"); err == nil {
+		t.Fatal("expected an error when a record has more columns than ColumnNames, got nil")
+	}
+}
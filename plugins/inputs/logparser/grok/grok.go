@@ -2,7 +2,9 @@ package grok
 
 import (
 	"bufio"
-	//"fmt"
+	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -13,6 +15,41 @@ import (
 	"github.com/influxdata/telegraf/internal"
 )
 
+// semantic timestamp types that may follow a capture name, e.g.
+// %{HTTPDATE:ts:ts-httpd}
+const (
+	SEMANTIC_TIME_HTTPD     = "ts-httpd"
+	SEMANTIC_TIME_SYSLOG    = "ts-syslog"
+	SEMANTIC_TIME_RFC3339   = "ts-rfc3339"
+	SEMANTIC_TIME_EPOCH     = "ts-epoch"
+	SEMANTIC_TIME_EPOCHNANO = "ts-epochnano"
+
+	SEMANTIC_INT         = "int"
+	SEMANTIC_FLOAT       = "float"
+	SEMANTIC_TAG         = "tag"
+	SEMANTIC_MEASUREMENT = "measurement"
+)
+
+// timeLayouts maps the builtin ts-* semantic names to their Go reference
+// time layout.
+var timeLayouts = map[string]string{
+	SEMANTIC_TIME_HTTPD:   "02/Jan/2006:15:04:05 -0700",
+	SEMANTIC_TIME_SYSLOG:  "Jan 2 15:04:05",
+	SEMANTIC_TIME_RFC3339: time.RFC3339,
+}
+
+// typeInfo describes how a named grok capture should be interpreted once
+// the pattern has been stripped of its inline modifier.
+type typeInfo struct {
+	// semantic is one of the SEMANTIC_* constants, or "ts" for a
+	// user-supplied Go time layout (see customLayouts).
+	semantic string
+}
+
+// modifierPattern matches the inline type/semantic modifier on a capture,
+// e.g. ":int", ":ts-httpd", or `:ts "Jan 2 15:04:05"`.
+var modifierPattern = regexp.MustCompile(`%{(\w+):(\w+):(ts\s+"[^"]+"|[\w-]+)}`)
+
 type Parser struct {
 	Pattern           string
 	CustomPatterns    string
@@ -27,6 +64,16 @@ type Parser struct {
 	FieldKeysFloat []string
 	fieldKeysFloat glob.Glob
 
+	// types holds the inline type/semantic decisions parsed out of
+	// Pattern by Compile, keyed by capture name.
+	types map[string]typeInfo
+	// customLayouts holds the Go time layout for captures that used a
+	// user-supplied `ts "<layout>"` modifier, keyed by capture name.
+	customLayouts map[string]string
+	// measurementKey is the name of the capture (if any) that supplies
+	// the metric name via a `:measurement` modifier.
+	measurementKey string
+
 	g *grok.Grok
 }
 
@@ -73,15 +120,57 @@ func (p *Parser) Compile() error {
 		}
 	}
 
+	p.Pattern, err = p.extractTypeModifiers(p.Pattern)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// extractTypeModifiers strips the optional third ":type" segment off of
+// every capture in pattern (e.g. "%{NUMBER:bytes:int}" -> "%{NUMBER:bytes}"),
+// recording the typing decision for each capture name in p.types so that
+// ParseLine can apply it after grok has done the matching.
+func (p *Parser) extractTypeModifiers(pattern string) (string, error) {
+	p.types = make(map[string]typeInfo)
+	p.customLayouts = make(map[string]string)
+	p.measurementKey = ""
+
+	matches := modifierPattern.FindAllStringSubmatch(pattern, -1)
+	for _, m := range matches {
+		name, modifier := m[2], m[3]
+
+		switch {
+		case modifier == SEMANTIC_MEASUREMENT:
+			p.measurementKey = name
+		case strings.HasPrefix(modifier, "ts "):
+			layout := strings.Trim(strings.TrimSpace(modifier[len("ts "):]), `"`)
+			p.customLayouts[name] = layout
+			p.types[name] = typeInfo{semantic: "ts"}
+		case modifier == SEMANTIC_TIME_HTTPD, modifier == SEMANTIC_TIME_SYSLOG,
+			modifier == SEMANTIC_TIME_RFC3339, modifier == SEMANTIC_TIME_EPOCH,
+			modifier == SEMANTIC_TIME_EPOCHNANO:
+			p.types[name] = typeInfo{semantic: modifier}
+		case modifier == SEMANTIC_INT, modifier == SEMANTIC_FLOAT, modifier == SEMANTIC_TAG:
+			p.types[name] = typeInfo{semantic: modifier}
+		default:
+			return "", fmt.Errorf("grok: unknown semantic type %q for capture %q", modifier, name)
+		}
+	}
+
+	return modifierPattern.ReplaceAllString(pattern, "%{$1:$2}"), nil
+}
+
 func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 	values, err := p.g.Parse(p.Pattern, line)
 	if err != nil {
 		return nil, err
 	}
 
+	measurement := "grok"
+	timestamp := time.Now()
+
 	fields := make(map[string]interface{})
 	tags := make(map[string]string)
 	for k, v := range values {
@@ -89,6 +178,62 @@ func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 			continue
 		}
 
+		if k == p.measurementKey {
+			measurement = v
+			continue
+		}
+
+		if t, ok := p.types[k]; ok {
+			switch t.semantic {
+			case SEMANTIC_TAG:
+				tags[k] = v
+				continue
+			case SEMANTIC_INT:
+				iv, err := strconv.ParseInt(v, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("grok: error converting %q to int: %s", k, err)
+				}
+				fields[k] = iv
+				continue
+			case SEMANTIC_FLOAT:
+				fv, err := strconv.ParseFloat(v, 64)
+				if err != nil {
+					return nil, fmt.Errorf("grok: error converting %q to float: %s", k, err)
+				}
+				fields[k] = fv
+				continue
+			case SEMANTIC_TIME_EPOCH:
+				ts, err := parseEpoch(v, time.Second)
+				if err != nil {
+					return nil, fmt.Errorf("grok: error parsing %q as epoch time: %s", k, err)
+				}
+				timestamp = ts
+				continue
+			case SEMANTIC_TIME_EPOCHNANO:
+				ts, err := parseEpoch(v, time.Nanosecond)
+				if err != nil {
+					return nil, fmt.Errorf("grok: error parsing %q as epoch-nano time: %s", k, err)
+				}
+				timestamp = ts
+				continue
+			case "ts":
+				ts, err := time.Parse(p.customLayouts[k], v)
+				if err != nil {
+					return nil, fmt.Errorf("grok: error parsing %q as time: %s", k, err)
+				}
+				timestamp = ts
+				continue
+			default:
+				// ts-httpd, ts-syslog, ts-rfc3339
+				ts, err := time.Parse(timeLayouts[t.semantic], v)
+				if err != nil {
+					return nil, fmt.Errorf("grok: error parsing %q as time: %s", k, err)
+				}
+				timestamp = ts
+				continue
+			}
+		}
+
 		if p.tagKeys != nil {
 			if p.tagKeys.Match(k) {
 				tags[k] = v
@@ -120,7 +265,21 @@ func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 		fields[k] = v
 	}
 
-	return telegraf.NewMetric("grok", tags, fields, time.Now())
+	return telegraf.NewMetric(measurement, tags, fields, timestamp)
+}
+
+// parseEpoch parses a unix timestamp given in unit-sized increments (seconds
+// or nanoseconds) since the epoch.
+func parseEpoch(v string, unit time.Duration) (time.Time, error) {
+	raw, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if unit == time.Nanosecond {
+		return time.Unix(0, raw), nil
+	}
+	return time.Unix(raw, 0), nil
 }
 
 func (p *Parser) addCustomPatterns() error {
@@ -0,0 +1,115 @@
+package grok
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExtractTypeModifiersStripsInlineType(t *testing.T) {
+	p := &Parser{}
+	stripped, err := p.extractTypeModifiers(
+		`%{NUMBER:bytes:int} %{NUMBER:latency:float} %{WORD:verb:tag} %{HTTPDATE:ts:ts-httpd} %{WORD:service:measurement}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := `%{NUMBER:bytes} %{NUMBER:latency} %{WORD:verb} %{HTTPDATE:ts} %{WORD:service}`
+	if stripped != want {
+		t.Fatalf("got pattern %q, want %q", stripped, want)
+	}
+
+	if p.types["bytes"].semantic != SEMANTIC_INT {
+		t.Errorf("bytes: got semantic %q, want %q", p.types["bytes"].semantic, SEMANTIC_INT)
+	}
+	if p.types["latency"].semantic != SEMANTIC_FLOAT {
+		t.Errorf("latency: got semantic %q, want %q", p.types["latency"].semantic, SEMANTIC_FLOAT)
+	}
+	if p.types["verb"].semantic != SEMANTIC_TAG {
+		t.Errorf("verb: got semantic %q, want %q", p.types["verb"].semantic, SEMANTIC_TAG)
+	}
+	if p.types["ts"].semantic != SEMANTIC_TIME_HTTPD {
+		t.Errorf("ts: got semantic %q, want %q", p.types["ts"].semantic, SEMANTIC_TIME_HTTPD)
+	}
+	if p.measurementKey != "service" {
+		t.Errorf("got measurementKey %q, want %q", p.measurementKey, "service")
+	}
+}
+
+func TestExtractTypeModifiersCustomLayout(t *testing.T) {
+	p := &Parser{}
+	stripped, err := p.extractTypeModifiers(`%{GREEDYDATA:ts:ts "2006-01-02 15:04:05"}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if stripped != `%{GREEDYDATA:ts}` {
+		t.Fatalf("got pattern %q, want %q", stripped, `%{GREEDYDATA:ts}`)
+	}
+	if p.types["ts"].semantic != "ts" {
+		t.Fatalf("got semantic %q, want %q", p.types["ts"].semantic, "ts")
+	}
+	if p.customLayouts["ts"] != "2006-01-02 15:04:05" {
+		t.Fatalf("got layout %q, want %q", p.customLayouts["ts"], "2006-01-02 15:04:05")
+	}
+}
+
+func TestExtractTypeModifiersUnknownSemantic(t *testing.T) {
+	p := &Parser{}
+	if _, err := p.extractTypeModifiers(`%{WORD:verb:bogus}`); err == nil {
+		t.Fatal("expected an error for an unknown semantic type, got nil")
+	}
+}
+
+func TestParseLineTypedCaptures(t *testing.T) {
+	p := &Parser{
+		Pattern: `%{NUMBER:bytes:int} %{NUMBER:latency:float} %{WORD:verb:tag} %{WORD:service:measurement}`,
+	}
+	if err := p.Compile(); err != nil {
+		t.Fatalf("Compile failed: %s", err)
+	}
+
+	m, err := p.ParseLine("200 1.5 GET myservice")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %s", err)
+	}
+
+	if m.Name() != "myservice" {
+		t.Errorf("got measurement %q, want %q", m.Name(), "myservice")
+	}
+	if m.Tags()["verb"] != "GET" {
+		t.Errorf("got tag verb=%q, want %q", m.Tags()["verb"], "GET")
+	}
+	if v, ok := m.Fields()["bytes"].(int64); !ok || v != 200 {
+		t.Errorf("got bytes=%v, want int64(200)", m.Fields()["bytes"])
+	}
+	if v, ok := m.Fields()["latency"].(float64); !ok || v != 1.5 {
+		t.Errorf("got latency=%v, want float64(1.5)", m.Fields()["latency"])
+	}
+}
+
+func TestParseLineTimestampSemantic(t *testing.T) {
+	p := &Parser{Pattern: `%{HTTPDATE:ts:ts-httpd}`}
+	if err := p.Compile(); err != nil {
+		t.Fatalf("Compile failed: %s", err)
+	}
+
+	m, err := p.ParseLine("10/Oct/2000:13:55:36 -0700")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %s", err)
+	}
+
+	want := time.Date(2000, time.October, 10, 13, 55, 36, 0, time.FixedZone("", -7*60*60))
+	if !m.Time().Equal(want) {
+		t.Errorf("got time %v, want %v", m.Time(), want)
+	}
+}
+
+func TestParseLineIntConversionError(t *testing.T) {
+	p := &Parser{Pattern: `%{WORD:bytes:int}`}
+	if err := p.Compile(); err != nil {
+		t.Fatalf("Compile failed: %s", err)
+	}
+
+	if _, err := p.ParseLine("notanumber"); err == nil {
+		t.Fatal("expected an error converting a non-numeric capture to int, got nil")
+	}
+}
@@ -0,0 +1,119 @@
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gobwas/glob"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+)
+
+// Parser turns a line of JSON into a telegraf.Metric. Nested objects are
+// flattened into dotted-path keys (e.g. {"a":{"b":1}} -> "a.b").
+type Parser struct {
+	MeasurementName string `toml:"measurement"`
+	TimeKey         string `toml:"time_key"`
+	TimeFormat      string `toml:"time_format"`
+
+	TagKeys      []string
+	tagKeys      glob.Glob
+	FieldKeysInt []string
+	fieldKeysInt glob.Glob
+}
+
+func (p *Parser) Compile() error {
+	var err error
+
+	if p.MeasurementName == "" {
+		p.MeasurementName = "json"
+	}
+
+	if len(p.TagKeys) > 0 {
+		if p.tagKeys, err = internal.CompileFilter(p.TagKeys); err != nil {
+			return err
+		}
+	}
+
+	if len(p.FieldKeysInt) > 0 {
+		if p.fieldKeysInt, err = internal.CompileFilter(p.FieldKeysInt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &raw); err != nil {
+		return nil, fmt.Errorf("json: %s", err)
+	}
+
+	flat := make(map[string]interface{})
+	flatten("", raw, flat)
+
+	timestamp := time.Now()
+	tags := make(map[string]string)
+	fields := make(map[string]interface{})
+
+	for k, v := range flat {
+		if p.TimeKey != "" && k == p.TimeKey {
+			ts, err := parseTime(v, p.TimeFormat)
+			if err != nil {
+				return nil, fmt.Errorf("json: error parsing time key %q: %s", k, err)
+			}
+			timestamp = ts
+			continue
+		}
+
+		if p.tagKeys != nil && p.tagKeys.Match(k) {
+			tags[k] = fmt.Sprintf("%v", v)
+			continue
+		}
+
+		if p.fieldKeysInt != nil && p.fieldKeysInt.Match(k) {
+			if f, ok := v.(float64); ok {
+				fields[k] = int64(f)
+				continue
+			}
+		}
+
+		fields[k] = v
+	}
+
+	return telegraf.NewMetric(p.MeasurementName, tags, fields, timestamp)
+}
+
+// flatten walks a decoded JSON object, writing every leaf value into out
+// keyed by its dotted path (prefix.key).
+func flatten(prefix string, in map[string]interface{}, out map[string]interface{}) {
+	for k, v := range in {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+
+		switch t := v.(type) {
+		case map[string]interface{}:
+			flatten(key, t, out)
+		default:
+			out[key] = v
+		}
+	}
+}
+
+func parseTime(v interface{}, format string) (time.Time, error) {
+	s := fmt.Sprintf("%v", v)
+	if format == "" || strings.EqualFold(format, "unix") {
+		f, ok := v.(float64)
+		if !ok {
+			return time.Time{}, fmt.Errorf("value %q is not a unix timestamp", s)
+		}
+		return time.Unix(int64(f), 0), nil
+	}
+	return time.Parse(format, s)
+}
@@ -0,0 +1,68 @@
+package json
+
+import "testing"
+
+func TestParseLineFlattensNestedObjects(t *testing.T) {
+	p := &Parser{}
+	if err := p.Compile(); err != nil {
+		t.Fatalf("Compile failed: %s", err)
+	}
+
+	m, err := p.ParseLine(`{"a":{"b":1},"c":"d"}`)
+	if err != nil {
+		t.Fatalf("ParseLine failed: %s", err)
+	}
+
+	if v, ok := m.Fields()["a.b"].(float64); !ok || v != 1 {
+		t.Errorf("got a.b=%v, want float64(1)", m.Fields()["a.b"])
+	}
+	if m.Fields()["c"] != "d" {
+		t.Errorf("got c=%v, want d", m.Fields()["c"])
+	}
+}
+
+func TestParseLineTimeKeyUnix(t *testing.T) {
+	p := &Parser{TimeKey: "ts"}
+	if err := p.Compile(); err != nil {
+		t.Fatalf("Compile failed: %s", err)
+	}
+
+	m, err := p.ParseLine(`{"ts":1000000000,"msg":"hi"}`)
+	if err != nil {
+		t.Fatalf("ParseLine failed: %s", err)
+	}
+	if m.Time().Unix() != 1000000000 {
+		t.Errorf("got unix time %d, want 1000000000", m.Time().Unix())
+	}
+	if _, ok := m.Fields()["ts"]; ok {
+		t.Error("time_key should be consumed as the timestamp, not also emitted as a field")
+	}
+}
+
+func TestParseLineTagAndIntFilters(t *testing.T) {
+	p := &Parser{TagKeys: []string{"host"}, FieldKeysInt: []string{"bytes"}}
+	if err := p.Compile(); err != nil {
+		t.Fatalf("Compile failed: %s", err)
+	}
+
+	m, err := p.ParseLine(`{"host":"web1","bytes":200}`)
+	if err != nil {
+		t.Fatalf("ParseLine failed: %s", err)
+	}
+	if m.Tags()["host"] != "web1" {
+		t.Errorf("got tags %v, want host=web1", m.Tags())
+	}
+	if v, ok := m.Fields()["bytes"].(int64); !ok || v != 200 {
+		t.Errorf("got bytes=%v, want int64(200)", m.Fields()["bytes"])
+	}
+}
+
+func TestParseLineInvalidJSON(t *testing.T) {
+	p := &Parser{}
+	if err := p.Compile(); err != nil {
+		t.Fatalf("Compile failed: %s", err)
+	}
+	if _, err := p.ParseLine("not json"); err == nil {
+		t.Fatal("expected an error for a non-JSON line, got nil")
+	}
+}
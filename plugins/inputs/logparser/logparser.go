@@ -1,19 +1,26 @@
 package logparser
 
 import (
+	"bytes"
 	"fmt"
 	"log"
 	"reflect"
 	"sync"
+	"time"
 
 	"github.com/hpcloud/tail"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/internal/globpath"
 	"github.com/influxdata/telegraf/plugins/inputs"
 
 	// Parsers:
+	"github.com/influxdata/telegraf/plugins/inputs/logparser/cef"
+	"github.com/influxdata/telegraf/plugins/inputs/logparser/csv"
 	"github.com/influxdata/telegraf/plugins/inputs/logparser/grok"
+	"github.com/influxdata/telegraf/plugins/inputs/logparser/json"
+	"github.com/influxdata/telegraf/plugins/inputs/logparser/regexp"
 )
 
 type LogParser interface {
@@ -21,25 +28,77 @@ type LogParser interface {
 	Compile() error
 }
 
+// FileConfig is one [[inputs.logparser.file]] block: a set of globs routed
+// to a single named parser subsection, with an optional measurement name
+// and default tags applied to every metric the parser produces.
+type FileConfig struct {
+	Paths               []string          `toml:"paths"`
+	Parser              string            `toml:"parser"`
+	MeasurementOverride string            `toml:"measurement_override"`
+	DefaultTags         map[string]string `toml:"default_tags"`
+}
+
+// watch is the runtime form of either a FileConfig block or the top-level
+// `files` list: the globs to tail and the parsers/overrides to apply to
+// whatever they match.
+type watch struct {
+	globs       []string
+	parsers     []LogParser
+	measurement string
+	tags        map[string]string
+}
+
+// trackedTailer is the bookkeeping kept for every file currently being
+// tailed, so a rescan can tell new files from ones already being watched
+// and know when a vanished file should be torn down.
+type trackedTailer struct {
+	tailer       *tail.Tail
+	inode        uint64
+	watch        *watch
+	// parsers are this file's own copies of w.parsers: any parser that
+	// keeps per-file learned state (e.g. csv.Parser's header-derived
+	// column names) is cloned per tailer so that files sharing one
+	// parser subsection can't corrupt each other's state.
+	parsers      []LogParser
+	missingSince time.Time
+}
+
 type LogParserPlugin struct {
-	Files         []string
-	FromBeginning bool
+	Files              []string
+	FromBeginning      bool
+	CheckpointFile     string            `toml:"checkpoint_file"`
+	CheckpointInterval internal.Duration `toml:"checkpoint_interval"`
+	RefreshInterval    internal.Duration `toml:"refresh_interval"`
 
-	tailers []*tail.Tail
-	wg      sync.WaitGroup
-	acc     telegraf.Accumulator
+	FileConfigs []FileConfig `toml:"file"`
+
+	tracked    map[string]*trackedTailer
+	watches    []*watch
+	multiline  *Multiline
+	checkpoint *Checkpointer
+	wg         sync.WaitGroup
+	acc        telegraf.Accumulator
+	stopRescan chan struct{}
 
 	sync.Mutex
 
-	// list of "active" log parsers
+	// every configured parser, used to validate FileConfig.Parser
+	// references and as the parser set for the legacy top-level `files`
 	parsers []LogParser
 
-	GrokParser *grok.Parser `toml:"grok"`
+	GrokParser      *grok.Parser    `toml:"grok"`
+	JSONParser      *json.Parser    `toml:"json"`
+	CSVParser       *csv.Parser     `toml:"csv"`
+	RegexpParser    *regexp.Parser  `toml:"regexp"`
+	CEFParser       *cef.Parser     `toml:"cef"`
+	MultilineConfig MultilineConfig `toml:"multiline"`
 }
 
 func NewLogParserPlugin() *LogParserPlugin {
 	return &LogParserPlugin{
-		FromBeginning: false,
+		FromBeginning:      false,
+		CheckpointInterval: internal.Duration{Duration: 10 * time.Second},
+		RefreshInterval:    internal.Duration{Duration: 60 * time.Second},
 	}
 }
 
@@ -57,6 +116,21 @@ const sampleConfig = `
   ## Read file from beginning.
   from_beginning = false
 
+  ## Re-glob every "files" and "[[inputs.logparser.file]]" path on this
+  ## interval, tailing any newly created matches and dropping tailers for
+  ## files that have been gone for more than two intervals. 0 disables
+  ## discovery of files created after Start.
+  # refresh_interval = "60s"
+
+  ## Persist each tailer's (filename, inode, offset) here so a restart can
+  ## resume tailing instead of re-reading from from_beginning/end-of-file.
+  ## from_beginning/end-of-file then only applies to a file with no
+  ## checkpoint, or whose inode no longer matches its checkpoint (the file
+  ## was rotated or truncated).
+  # checkpoint_file = "/var/run/telegraf/logparser.checkpoint"
+  ## how often the checkpoint file is flushed to disk
+  # checkpoint_interval = "10s"
+
   ## For parsing logstash-style "grok" patterns:
   [inputs.logparser.grok]
     pattern = "%{}"
@@ -65,6 +139,53 @@ const sampleConfig = `
       NGUSER %{NGUSERNAME}
       NGINXACCESS %{IPORHOST:clientip} %{NGUSER:ident} %{NGUSER:auth} \[%{HTTPDATE:timestamp}\] "%{WORD:verb} %{URIPATHPARAM:request} HTTP/%{NUMBER:httpversion}" %{NUMBER:response} (?:%{NUMBER:bytes}|-) (?:"(?:%{URI:referrer}|-)"|%{QS:referrer}) %{QS:agent}
 	'''
+
+  ## Other formats can be parsed alongside (or instead of) grok, each in
+  ## its own subsection:
+  # [inputs.logparser.json]
+  #   time_key = "time"
+  #   time_format = "2006-01-02T15:04:05Z07:00"
+  #   tag_keys = ["host"]
+  # [inputs.logparser.csv]
+  #   header_row_count = 1
+  #   tag_columns = ["host"]
+  # [inputs.logparser.regexp]
+  #   pattern = '(?P<clientip>\S+) \S+ \S+ \[(?P<timestamp>[^\]]+)\]'
+  # [inputs.logparser.cef]
+  #   measurement = "cef"
+
+  ## Route specific globs to a specific parser subsection above, instead of
+  ## (or in addition to) trying every parser against "files". Repeat this
+  ## block for each file type a single telegraf process needs to tail.
+  # [[inputs.logparser.file]]
+  #   paths = ["/var/log/nginx/access.log"]
+  #   parser = "grok"
+  # [[inputs.logparser.file]]
+  #   paths = ["/var/log/myapp/*.json"]
+  #   parser = "json"
+  #   measurement_override = "myapp"
+  #   default_tags = { environment = "production" }
+
+  ## Multiline parsing is opt-in. Currently supports re-assembling records
+  ## whose continuation lines either start with whitespace (Java stack
+  ## traces) or lack a leading timestamp (MySQL slow query log, Postgres,
+  ## Rails, etc.)
+  # [inputs.logparser.multiline]
+    ## pattern, if a match is found the line is part of a multi-line event
+    # pattern = "^\\s"
+    ## match can be "next" or "previous"
+    ##   "next"     - pattern identifies a continuation of the record that
+    ##                follows it (e.g. a trailing "\" line-continuation marker);
+    ##                a non-matching line completes and flushes the record
+    ##   "previous" - pattern identifies a continuation of the previous record
+    # match = "previous"
+    ## negate the pattern match
+    # negate = false
+    ## flush a buffered (possibly partial) record after this much inactivity
+    # timeout = "5s"
+    ## safety caps on how large a single buffered record may grow
+    # max_lines = 500
+    # max_bytes = 10485760
 `
 
 func (l *LogParserPlugin) SampleConfig() string {
@@ -85,9 +206,65 @@ func (l *LogParserPlugin) Start(acc telegraf.Accumulator) error {
 
 	l.acc = acc
 
-	// Looks for fields which implement LogParser interface
-	l.parsers = make([]LogParser, 0)
+	parsersByName, err := l.buildParsers()
+	if err != nil {
+		return err
+	}
+
+	watches, err := l.buildWatches(parsersByName)
+	if err != nil {
+		return err
+	}
+	l.watches = watches
+
+	multiline, err := l.MultilineConfig.NewMultiline()
+	if err != nil {
+		return err
+	}
+	l.multiline = multiline
+
+	if l.CheckpointFile != "" {
+		if err := checkpointDir(l.CheckpointFile); err != nil {
+			return err
+		}
+		l.checkpoint = NewCheckpointer(l.CheckpointFile, l.CheckpointInterval.Duration)
+		if err := l.checkpoint.Load(); err != nil {
+			return err
+		}
+	} else {
+		l.checkpoint = NewCheckpointer("", l.CheckpointInterval.Duration)
+	}
+
+	var defaultSeek tail.SeekInfo
+	if !l.FromBeginning {
+		defaultSeek.Whence = 2
+		defaultSeek.Offset = 0
+	}
+
+	l.tracked = make(map[string]*trackedTailer)
+	l.scan(defaultSeek)
+
+	l.checkpoint.Start()
+
+	if l.RefreshInterval.Duration > 0 {
+		l.stopRescan = make(chan struct{})
+		l.wg.Add(1)
+		go l.rescanner(defaultSeek)
+	}
+
+	return nil
+}
+
+// buildParsers looks for fields which implement the LogParser interface.
+// Several parser subsections (grok, json, csv, regexp, cef, ...) may be
+// configured at once; it returns them keyed by their TOML name so
+// [[inputs.logparser.file]] blocks can select one by name.
+func (l *LogParserPlugin) buildParsers() (map[string]LogParser, error) {
+	byName := make(map[string]LogParser)
+	l.parsers = l.parsers[:0]
+
 	s := reflect.ValueOf(l).Elem()
+	t := s.Type()
 	for i := 0; i < s.NumField(); i++ {
 		f := s.Field(i)
 
@@ -95,100 +272,304 @@ func (l *LogParserPlugin) Start(acc telegraf.Accumulator) error {
 			continue
 		}
 
-		if lpPlugin, ok := f.Interface().(LogParser); ok {
-			if reflect.ValueOf(lpPlugin).IsNil() {
-				continue
-			}
-			l.parsers = append(l.parsers, lpPlugin)
+		lpPlugin, ok := f.Interface().(LogParser)
+		if !ok || reflect.ValueOf(lpPlugin).IsNil() {
+			continue
 		}
+
+		name := t.Field(i).Tag.Get("toml")
+		if name == "" {
+			name = t.Field(i).Name
+		}
+		byName[name] = lpPlugin
+		l.parsers = append(l.parsers, lpPlugin)
 	}
 
 	if len(l.parsers) == 0 {
-		return fmt.Errorf("ERROR: logparser input plugin: no parsers defined.")
+		return nil, fmt.Errorf("ERROR: logparser input plugin: no parsers defined.")
 	}
 
-	// compile all log parser patterns:
 	for _, parser := range l.parsers {
 		if err := parser.Compile(); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	var seek tail.SeekInfo
-	if !l.FromBeginning {
-		seek.Whence = 2
-		seek.Offset = 0
+	return byName, nil
+}
+
+// buildWatches turns the legacy top-level `files` list and any
+// [[inputs.logparser.file]] blocks into watches: glob sets paired with the
+// parser(s), measurement override and default tags that apply to whatever
+// they match.
+func (l *LogParserPlugin) buildWatches(parsersByName map[string]LogParser) ([]*watch, error) {
+	var watches []*watch
+
+	if len(l.Files) > 0 {
+		watches = append(watches, &watch{globs: l.Files, parsers: l.parsers})
 	}
 
-	var errS string
-	// Create a "tailer" for each file
-	for _, filepath := range l.Files {
-		g, err := globpath.Compile(filepath)
-		if err != nil {
-			log.Printf("ERROR Glob %s failed to compile, %s", filepath, err)
-		}
-		for file, _ := range g.Match() {
-			tailer, err := tail.TailFile(file,
-				tail.Config{
-					ReOpen:   true,
-					Follow:   true,
-					Location: &seek,
-				})
+	for _, fc := range l.FileConfigs {
+		parser, ok := parsersByName[fc.Parser]
+		if !ok {
+			return nil, fmt.Errorf(
+				"logparser: [[inputs.logparser.file]] references unknown parser %q", fc.Parser)
+		}
+		watches = append(watches, &watch{
+			globs:       fc.Paths,
+			parsers:     []LogParser{parser},
+			measurement: fc.MeasurementOverride,
+			tags:        fc.DefaultTags,
+		})
+	}
+
+	if len(watches) == 0 {
+		return nil, fmt.Errorf("logparser: no files or [[inputs.logparser.file]] blocks configured")
+	}
+
+	return watches, nil
+}
+
+// rescanner periodically re-globs every watch, picking up files created
+// since the last scan and dropping tailers for files that have been gone
+// for a while.
+func (l *LogParserPlugin) rescanner(defaultSeek tail.SeekInfo) {
+	defer l.wg.Done()
+
+	ticker := time.NewTicker(l.RefreshInterval.Duration)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			l.Lock()
+			// Stop() may have closed stopRescan and be waiting on l.wg
+			// right as the ticker fired; bail instead of scanning (and
+			// possibly starting new tailers) after shutdown began.
+			select {
+			case <-l.stopRescan:
+				l.Unlock()
+				return
+			default:
+			}
+			l.scan(defaultSeek)
+			l.Unlock()
+		case <-l.stopRescan:
+			return
+		}
+	}
+}
+
+// scan globs every configured watch: any newly matching file gets a
+// tailer, and any previously-tracked file that has stopped matching is torn
+// down once more than two refresh intervals have passed since the first
+// scan that found it missing (giving it time to reappear, e.g.
+// mid-rotation). Must be called with l locked.
+func (l *LogParserPlugin) scan(defaultSeek tail.SeekInfo) {
+	seen := make(map[string]bool)
+
+	for _, w := range l.watches {
+		for _, pattern := range w.globs {
+			g, err := globpath.Compile(pattern)
 			if err != nil {
-				errS += err.Error() + " "
+				log.Printf("ERROR Glob %s failed to compile, %s", pattern, err)
 				continue
 			}
-			// create a goroutine for each "tailer"
-			l.wg.Add(1)
-			go l.receiver(tailer)
-			l.tailers = append(l.tailers, tailer)
+			for file := range g.Match() {
+				seen[file] = true
+				if tt, ok := l.tracked[file]; ok {
+					tt.missingSince = time.Time{}
+					continue
+				}
+				l.startTailer(file, w, defaultSeek)
+			}
 		}
 	}
 
-	if errS != "" {
-		return fmt.Errorf(errS)
+	grace := 2 * l.RefreshInterval.Duration
+	for file, tt := range l.tracked {
+		if seen[file] {
+			continue
+		}
+		if tt.missingSince.IsZero() {
+			tt.missingSince = time.Now()
+			continue
+		}
+		if time.Since(tt.missingSince) > grace {
+			l.stopTailer(file, tt)
+		}
 	}
-	return nil
+}
+
+func (l *LogParserPlugin) startTailer(file string, w *watch, defaultSeek tail.SeekInfo) {
+	inode, err := fileInode(file)
+	if err != nil {
+		log.Printf("ERROR %s: %s", file, err)
+		return
+	}
+
+	seek := defaultSeek
+	if cp, ok := l.checkpoint.Seek(file); ok && cp.Inode == inode {
+		seek = tail.SeekInfo{Whence: 0, Offset: cp.Offset}
+	}
+
+	tailer, err := tail.TailFile(file,
+		tail.Config{
+			ReOpen:   true,
+			Follow:   true,
+			Location: &seek,
+		})
+	if err != nil {
+		log.Printf("ERROR tailing file %s, %s", file, err)
+		return
+	}
+
+	tt := &trackedTailer{tailer: tailer, inode: inode, watch: w, parsers: clonedParsers(w.parsers)}
+	l.tracked[file] = tt
+
+	// create a goroutine for each "tailer"
+	l.wg.Add(1)
+	go l.receiver(tt)
+}
+
+// clonedParsers gives each tailed file its own copy of any parser that
+// learns per-file state (see trackedTailer.parsers); parsers with no such
+// state are shared as-is.
+func clonedParsers(parsers []LogParser) []LogParser {
+	out := make([]LogParser, len(parsers))
+	for i, p := range parsers {
+		switch v := p.(type) {
+		case *csv.Parser:
+			out[i] = v.Clone()
+		default:
+			out[i] = p
+		}
+	}
+	return out
+}
+
+func (l *LogParserPlugin) stopTailer(file string, tt *trackedTailer) {
+	if err := tt.tailer.Stop(); err != nil {
+		log.Printf("ERROR stopping tail on file %s\n", file)
+	}
+	tt.tailer.Cleanup()
+	delete(l.tracked, file)
 }
 
 // this is launched as a goroutine to continuously watch a tailed logfile
 // for changes, parse any incoming msgs, and add to the accumulator.
-func (l *LogParserPlugin) receiver(tailer *tail.Tail) {
+func (l *LogParserPlugin) receiver(tt *trackedTailer) {
 	defer l.wg.Done()
 
-	var m telegraf.Metric
-	var err error
-	var line *tail.Line
-	for line = range tailer.Lines {
-		if line.Err != nil {
-			log.Printf("ERROR tailing file %s, Error: %s\n",
-				tailer.Filename, err)
+	tailer := tt.tailer
+
+	var buffer bytes.Buffer
+	var timer *time.Timer
+	var timeout <-chan time.Time
+
+	linesCh := tailer.Lines
+	for {
+		if l.multiline.IsEnabled() {
+			if timer == nil {
+				timer = time.NewTimer(l.multiline.config.Timeout.Duration)
+			}
+			timeout = timer.C
+		}
+
+		select {
+		case line, ok := <-linesCh:
+			if !ok {
+				// tailer closed; flush whatever is left buffered.
+				if record := l.multiline.Flush(&buffer); record != "" {
+					l.parseAndAdd(tailer, record, tt)
+				}
+				return
+			}
+
+			if line.Err != nil {
+				log.Printf("ERROR tailing file %s, Error: %s\n",
+					tailer.Filename, line.Err)
+				continue
+			}
+
+			if timer != nil {
+				timer.Stop()
+				timer = nil
+			}
+
+			if !l.multiline.IsEnabled() {
+				l.parseAndAdd(tailer, line.Text, tt)
+			} else if record := l.multiline.ProcessLine(line.Text, &buffer); record != "" {
+				l.parseAndAdd(tailer, record, tt)
+			}
+
+			l.checkpoint.Update(tailer.Filename, tt.inode, line.SeekInfo.Offset)
+		case <-timeout:
+			timer = nil
+			if record := l.multiline.Flush(&buffer); record != "" {
+				l.parseAndAdd(tailer, record, tt)
+			}
+		}
+	}
+}
+
+// parseAndAdd runs record through every parser assigned to tt (tt's own
+// clones, so learned per-file state like csv.Parser's column names never
+// leaks between files) and adds whatever metrics result to the
+// accumulator, applying tt.watch's measurement override and default tags.
+func (l *LogParserPlugin) parseAndAdd(tailer *tail.Tail, record string, tt *trackedTailer) {
+	w := tt.watch
+	for _, parser := range tt.parsers {
+		m, err := parser.ParseLine(record)
+		if err != nil {
+			log.Printf("Malformed log line in %s: [%s], Error: %s\n",
+				tailer.Filename, record, err)
 			continue
 		}
-		for _, parser := range l.parsers {
-			m, err = parser.ParseLine(line.Text)
-			if err == nil {
-				l.acc.AddFields(m.Name(), m.Fields(), m.Tags(), m.Time())
-			} else {
-				log.Printf("Malformed log line in %s: [%s], Error: %s\n",
-					tailer.Filename, line.Text, err)
+		if m == nil {
+			continue
+		}
+
+		name := m.Name()
+		if w.measurement != "" {
+			name = w.measurement
+		}
+
+		tags := m.Tags()
+		for k, v := range w.tags {
+			if _, ok := tags[k]; !ok {
+				tags[k] = v
 			}
 		}
+
+		l.acc.AddFields(name, m.Fields(), tags, m.Time())
 	}
 }
 
 func (l *LogParserPlugin) Stop() {
 	l.Lock()
-	defer l.Unlock()
 
-	for _, t := range l.tailers {
-		err := t.Stop()
-		if err != nil {
-			log.Printf("ERROR stopping tail on file %s\n", t.Filename)
+	if l.stopRescan != nil {
+		close(l.stopRescan)
+	}
+
+	for file, tt := range l.tracked {
+		if err := tt.tailer.Stop(); err != nil {
+			log.Printf("ERROR stopping tail on file %s\n", file)
 		}
-		t.Cleanup()
+		tt.tailer.Cleanup()
 	}
+
+	// wg.Wait must not happen under the lock: rescanner needs to take
+	// l.Lock() (to notice stopRescan is closed) before it can exit and
+	// call wg.Done.
+	l.Unlock()
+
 	l.wg.Wait()
+
+	if err := l.checkpoint.Stop(); err != nil {
+		log.Printf("ERROR flushing logparser checkpoint file: %s\n", err)
+	}
 }
 
 func init() {
@@ -0,0 +1,144 @@
+package logparser
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf/internal"
+)
+
+// MatchWhichLine determines whether a line matching Pattern continues into
+// the line that follows it ("next") or is a continuation line that belongs
+// with whatever came before it ("previous").
+type MatchWhichLine int
+
+const (
+	Previous MatchWhichLine = iota
+	Next
+)
+
+// UnmarshalTOML decodes the "previous" or "next" value of the multiline
+// "match" option.
+func (w *MatchWhichLine) UnmarshalTOML(data []byte) error {
+	switch strings.ToLower(strings.Trim(string(data), `"'`)) {
+	case "previous":
+		*w = Previous
+	case "next":
+		*w = Next
+	default:
+		return fmt.Errorf("logparser: unknown multiline match %s, want \"previous\" or \"next\"", data)
+	}
+	return nil
+}
+
+// MultilineConfig is the [inputs.logparser.multiline] TOML config block.
+type MultilineConfig struct {
+	Pattern        string
+	MatchWhichLine MatchWhichLine `toml:"match"`
+	InvertMatch    bool           `toml:"negate"`
+	Timeout        *internal.Duration
+
+	MaxLines int `toml:"max_lines"`
+	MaxBytes int `toml:"max_bytes"`
+}
+
+// NewMultiline validates the config and builds a ready-to-use Multiline. A
+// zero-value MultilineConfig (Pattern == "") is valid and yields a disabled
+// Multiline, so logparser can always hold one and check IsEnabled().
+func (c *MultilineConfig) NewMultiline() (*Multiline, error) {
+	var r *regexp.Regexp
+	var err error
+
+	if c.Pattern != "" {
+		r, err = regexp.Compile(c.Pattern)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if c.MaxLines == 0 {
+		c.MaxLines = 500
+	}
+
+	if c.Timeout == nil || c.Timeout.Duration.Nanoseconds() == int64(0) {
+		c.Timeout = &internal.Duration{Duration: 5 * time.Second}
+	}
+
+	return &Multiline{
+		config:        c,
+		patternRegexp: r,
+	}, nil
+}
+
+// Multiline buffers tail.Line text for a single tailer until a complete
+// record (as identified by MultilineConfig.Pattern) has been assembled.
+type Multiline struct {
+	config        *MultilineConfig
+	patternRegexp *regexp.Regexp
+}
+
+func (m *Multiline) IsEnabled() bool {
+	return m.patternRegexp != nil
+}
+
+// ProcessLine folds text into buffer according to the configured pattern and
+// match direction. It returns a completed record once one is ready to be
+// handed to the parsers, or "" if text was absorbed into buffer and the
+// record is still incomplete.
+func (m *Multiline) ProcessLine(text string, buffer *bytes.Buffer) string {
+	if m.matchString(text) {
+		if buffer.Len() > 0 {
+			buffer.WriteString("\n")
+		}
+		buffer.WriteString(text)
+
+		if m.config.MaxLines > 0 && lineCount(buffer) >= m.config.MaxLines {
+			return m.Flush(buffer)
+		}
+		if m.config.MaxBytes > 0 && buffer.Len() >= m.config.MaxBytes {
+			return m.Flush(buffer)
+		}
+		return ""
+	}
+
+	if m.config.MatchWhichLine == Previous {
+		// text starts a new record; whatever was buffered is complete.
+		previous := m.Flush(buffer)
+		buffer.WriteString(text)
+		return previous
+	}
+
+	// MatchWhichLine == Next: text is the last line of the record that was
+	// being buffered.
+	if buffer.Len() > 0 {
+		buffer.WriteString("\n")
+	}
+	buffer.WriteString(text)
+	return m.Flush(buffer)
+}
+
+// Flush returns whatever has been buffered so far (joined with "\n") as a
+// completed record, resetting buffer. It is used both when a record
+// naturally completes and when a timeout forces a partial record out.
+func (m *Multiline) Flush(buffer *bytes.Buffer) string {
+	if buffer.Len() == 0 {
+		return ""
+	}
+	text := buffer.String()
+	buffer.Reset()
+	return text
+}
+
+func (m *Multiline) matchString(text string) bool {
+	return m.patternRegexp.MatchString(text) != m.config.InvertMatch
+}
+
+func lineCount(buffer *bytes.Buffer) int {
+	if buffer.Len() == 0 {
+		return 0
+	}
+	return bytes.Count(buffer.Bytes(), []byte("\n")) + 1
+}
@@ -0,0 +1,90 @@
+package logparser
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMultilineMatchPrevious(t *testing.T) {
+	c := &MultilineConfig{Pattern: `^\s`, MatchWhichLine: Previous}
+	m, err := c.NewMultiline()
+	if err != nil {
+		t.Fatalf("NewMultiline failed: %s", err)
+	}
+
+	var buf bytes.Buffer
+	if got := m.ProcessLine("2016/01/01 error", &buf); got != "" {
+		t.Fatalf("got %q, want \"\" (first line has nothing to flush)", got)
+	}
+	if got := m.ProcessLine("  at foo.go:1", &buf); got != "" {
+		t.Fatalf("got %q, want \"\" (continuation line absorbed)", got)
+	}
+	if got := m.ProcessLine("2016/01/01 next", &buf); got != "2016/01/01 error\n  at foo.go:1" {
+		t.Fatalf("got %q, want the completed first record", got)
+	}
+}
+
+func TestMultilineMatchNext(t *testing.T) {
+	c := &MultilineConfig{Pattern: `\\$`, MatchWhichLine: Next}
+	m, err := c.NewMultiline()
+	if err != nil {
+		t.Fatalf("NewMultiline failed: %s", err)
+	}
+
+	var buf bytes.Buffer
+	if got := m.ProcessLine(`line one \`, &buf); got != "" {
+		t.Fatalf("got %q, want \"\" (continuation marker buffers)", got)
+	}
+	if got := m.ProcessLine("line two", &buf); got != "line one \\\nline two" {
+		t.Fatalf("got %q, want the completed record", got)
+	}
+}
+
+func TestMultilineNegate(t *testing.T) {
+	c := &MultilineConfig{Pattern: `^ERROR`, MatchWhichLine: Previous, InvertMatch: true}
+	m, err := c.NewMultiline()
+	if err != nil {
+		t.Fatalf("NewMultiline failed: %s", err)
+	}
+
+	var buf bytes.Buffer
+	m.ProcessLine("ERROR something broke", &buf)
+	m.ProcessLine("  stack frame 1", &buf)
+	got := m.ProcessLine("ERROR next error", &buf)
+	if got != "ERROR something broke\n  stack frame 1" {
+		t.Fatalf("got %q, want the completed record", got)
+	}
+}
+
+func TestMultilineMaxLinesFlushes(t *testing.T) {
+	c := &MultilineConfig{Pattern: `^\s`, MatchWhichLine: Previous, MaxLines: 2}
+	m, err := c.NewMultiline()
+	if err != nil {
+		t.Fatalf("NewMultiline failed: %s", err)
+	}
+
+	var buf bytes.Buffer
+	m.ProcessLine("start", &buf)
+	if got := m.ProcessLine("  cont", &buf); got != "start\n  cont" {
+		t.Fatalf("got %q, want a flush once MaxLines is reached", got)
+	}
+}
+
+func TestMultilineDisabledWhenNoPattern(t *testing.T) {
+	c := &MultilineConfig{}
+	m, err := c.NewMultiline()
+	if err != nil {
+		t.Fatalf("NewMultiline failed: %s", err)
+	}
+	if m.IsEnabled() {
+		t.Fatal("expected a Multiline with no Pattern to be disabled")
+	}
+}
+
+func TestMultilineFlushEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	m := &Multiline{config: &MultilineConfig{}}
+	if got := m.Flush(&buf); got != "" {
+		t.Fatalf("got %q, want \"\" for an empty buffer", got)
+	}
+}
@@ -0,0 +1,108 @@
+package regexp
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/gobwas/glob"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+)
+
+// Parser matches a line against Pattern, a Go regular expression with named
+// capture groups (e.g. "(?P<clientip>\\S+) (?P<bytes>\\d+)"), and maps the
+// captures to fields/tags using the same typed-key filters as the grok
+// parser.
+type Parser struct {
+	MeasurementName string `toml:"measurement"`
+	Pattern         string
+
+	TagKeys        []string
+	tagKeys        glob.Glob
+	FieldKeysInt   []string
+	fieldKeysInt   glob.Glob
+	FieldKeysFloat []string
+	fieldKeysFloat glob.Glob
+
+	re *regexp.Regexp
+}
+
+func (p *Parser) Compile() error {
+	var err error
+
+	if p.MeasurementName == "" {
+		p.MeasurementName = "regexp"
+	}
+
+	p.re, err = regexp.Compile(p.Pattern)
+	if err != nil {
+		return err
+	}
+
+	if len(p.TagKeys) > 0 {
+		if p.tagKeys, err = internal.CompileFilter(p.TagKeys); err != nil {
+			return err
+		}
+	}
+
+	if len(p.FieldKeysInt) > 0 {
+		if p.fieldKeysInt, err = internal.CompileFilter(p.FieldKeysInt); err != nil {
+			return err
+		}
+	}
+
+	if len(p.FieldKeysFloat) > 0 {
+		if p.fieldKeysFloat, err = internal.CompileFilter(p.FieldKeysFloat); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	match := p.re.FindStringSubmatch(line)
+	if match == nil {
+		return nil, fmt.Errorf("regexp: pattern does not match line")
+	}
+
+	tags := make(map[string]string)
+	fields := make(map[string]interface{})
+
+	for i, name := range p.re.SubexpNames() {
+		if i == 0 || name == "" || match[i] == "" {
+			continue
+		}
+		v := match[i]
+
+		if p.tagKeys != nil && p.tagKeys.Match(name) {
+			tags[name] = v
+			continue
+		}
+
+		if p.fieldKeysInt != nil && p.fieldKeysInt.Match(name) {
+			iv, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("regexp: error converting %q to int: %s", name, err)
+			}
+			fields[name] = iv
+			continue
+		}
+
+		if p.fieldKeysFloat != nil && p.fieldKeysFloat.Match(name) {
+			fv, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("regexp: error converting %q to float: %s", name, err)
+			}
+			fields[name] = fv
+			continue
+		}
+
+		fields[name] = v
+	}
+
+	return telegraf.NewMetric(p.MeasurementName, tags, fields, time.Now())
+}
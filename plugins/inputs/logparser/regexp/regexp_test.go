@@ -0,0 +1,57 @@
+package regexp
+
+import "testing"
+
+func TestParseLineNamedCaptures(t *testing.T) {
+	p := &Parser{
+		Pattern:      `(?P<clientip>\S+) (?P<verb>\S+) (?P<bytes>\d+)`,
+		TagKeys:      []string{"verb"},
+		FieldKeysInt: []string{"bytes"},
+	}
+	if err := p.Compile(); err != nil {
+		t.Fatalf("Compile failed: %s", err)
+	}
+
+	m, err := p.ParseLine("127.0.0.1 GET 200")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %s", err)
+	}
+
+	if m.Tags()["verb"] != "GET" {
+		t.Errorf("got tags %v, want verb=GET", m.Tags())
+	}
+	if m.Fields()["clientip"] != "127.0.0.1" {
+		t.Errorf("got clientip=%v, want 127.0.0.1", m.Fields()["clientip"])
+	}
+	if v, ok := m.Fields()["bytes"].(int64); !ok || v != 200 {
+		t.Errorf("got bytes=%v, want int64(200)", m.Fields()["bytes"])
+	}
+}
+
+func TestParseLineFloatField(t *testing.T) {
+	p := &Parser{
+		Pattern:        `(?P<latency>\d+\.\d+)`,
+		FieldKeysFloat: []string{"latency"},
+	}
+	if err := p.Compile(); err != nil {
+		t.Fatalf("Compile failed: %s", err)
+	}
+
+	m, err := p.ParseLine("1.5")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %s", err)
+	}
+	if v, ok := m.Fields()["latency"].(float64); !ok || v != 1.5 {
+		t.Errorf("got latency=%v, want float64(1.5)", m.Fields()["latency"])
+	}
+}
+
+func TestParseLineNoMatch(t *testing.T) {
+	p := &Parser{Pattern: `^ERROR`}
+	if err := p.Compile(); err != nil {
+		t.Fatalf("Compile failed: %s", err)
+	}
+	if _, err := p.ParseLine("INFO all fine"); err == nil {
+		t.Fatal("expected an error when Pattern does not match the line, got nil")
+	}
+}